@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// apiKeyFromRequest extracts a bearer token from either the Authorization
+// header or the simpler X-API-Key header.
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// ipRateLimiter throttles unauthenticated submissions per remote IP
+// within a sliding window, used for "open" submission mode.
+type ipRateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	requests map[string][]time.Time
+}
+
+func newIPRateLimiter(limit int, window time.Duration) *ipRateLimiter {
+	return &ipRateLimiter{
+		limit:    limit,
+		window:   window,
+		requests: make(map[string][]time.Time),
+	}
+}
+
+// Allow reports whether ip is still within its submission quota,
+// recording the attempt if so.
+func (l *ipRateLimiter) Allow(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := time.Now().Add(-l.window)
+
+	kept := l.requests[ip][:0]
+	for _, t := range l.requests[ip] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept) >= l.limit {
+		l.requests[ip] = kept
+		return false
+	}
+
+	l.requests[ip] = append(kept, time.Now())
+	return true
+}
+
+// authorizeSubmission enforces the feed-submission auth policy: a valid
+// API key always bypasses rate limiting; without one, "closed" mode
+// rejects the request outright while "open" mode falls back to per-IP
+// throttling. It writes an error response itself on rejection.
+func (app *App) authorizeSubmission(w http.ResponseWriter, r *http.Request) (keyName string, ok bool) {
+	if key := apiKeyFromRequest(r); key != "" {
+		if app.apiKeys != nil {
+			if name, valid := app.apiKeys.Verify(key); valid {
+				return name, true
+			}
+		}
+		if err := renderMessage(w, http.StatusUnauthorized, "Error", "Invalid API key"); err != nil {
+			log.WithError(err).Error("error rendering message template")
+		}
+		return "", false
+	}
+
+	if app.conf.SubmissionMode == "closed" {
+		if err := renderMessage(w, http.StatusUnauthorized, "Error", "Feed submission requires an API key"); err != nil {
+			log.WithError(err).Error("error rendering message template")
+		}
+		return "", false
+	}
+
+	if app.rateLimiter != nil && !app.rateLimiter.Allow(remoteIP(app, r)) {
+		if err := renderMessage(w, http.StatusTooManyRequests, "Error", "Too many submissions, please try again later"); err != nil {
+			log.WithError(err).Error("error rendering message template")
+		}
+		return "", false
+	}
+
+	return "", true
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// NewCLIApp builds the root rss2twtxt CLI application and registers its
+// subcommands, including `keys` for managing feed-submission API keys.
+func NewCLIApp() *cli.App {
+	return &cli.App{
+		Name:  "rss2twtxt",
+		Usage: "RSS/Atom to twtxt feed aggregator service",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "keys",
+				Usage: "path to the API keys store",
+				Value: "keys.toml",
+			},
+		},
+		Commands: []*cli.Command{
+			KeysCommand,
+		},
+	}
+}
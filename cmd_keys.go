@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/prologic/rss2twtxt/lib/apikeys"
+)
+
+// KeysCommand implements `rss2twtxt keys`, for managing the API keys
+// used to authenticate feed submissions.
+var KeysCommand = &cli.Command{
+	Name:  "keys",
+	Usage: "manage API keys for authenticated feed submission",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "add",
+			Usage:     "generate and store a new API key",
+			ArgsUsage: "<label>",
+			Action:    keysAddAction,
+		},
+	},
+}
+
+func keysAddAction(c *cli.Context) error {
+	label := c.Args().First()
+	if label == "" {
+		return fmt.Errorf("a label is required, e.g. rss2twtxt keys add ci-bot")
+	}
+
+	path := c.String("keys")
+	if path == "" {
+		path = "keys.toml"
+	}
+
+	store, err := apikeys.Load(path)
+	if err != nil {
+		return fmt.Errorf("error loading %s: %w", path, err)
+	}
+
+	key, err := store.Add(label)
+	if err != nil {
+		return fmt.Errorf("error generating key: %w", err)
+	}
+
+	if err := store.Save(path); err != nil {
+		return fmt.Errorf("error saving %s: %w", path, err)
+	}
+
+	fmt.Printf("Generated API key for %q:\n\n  %s\n\nStore this somewhere safe -- it will not be shown again.\n", label, key)
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rickb777/accept"
+	log "github.com/sirupsen/logrus"
+)
+
+// twtxtEntry is a single parsed line of a twtxt feed file.
+type twtxtEntry struct {
+	Timestamp time.Time
+	Content   string
+}
+
+// readTwtxtEntries parses a twtxt file's `<RFC3339 timestamp>\t<content>`
+// lines, skipping blank lines and comments.
+func readTwtxtEntries(filename string) ([]twtxtEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []twtxtEntry
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		ts, err := time.Parse(time.RFC3339, parts[0])
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, twtxtEntry{Timestamp: ts, Content: parts[1]})
+	}
+
+	return entries, scanner.Err()
+}
+
+func entryID(e twtxtEntry) string {
+	sum := sha1.Sum([]byte(e.Timestamp.Format(time.RFC3339) + e.Content))
+	return fmt.Sprintf("%x", sum)
+}
+
+func entryTitle(content string) string {
+	title := strings.SplitN(content, "\n", 2)[0]
+	if len(title) > 64 {
+		title = title[:64] + "…"
+	}
+	return title
+}
+
+// atomFeed is the Atom 1.0 root element.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func buildAtomFeed(app *App, name string, entries []twtxtEntry, modTime time.Time) atomFeed {
+	feedURL := fmt.Sprintf("%s/%s.atom", app.conf.BaseURL, name)
+	htmlURL := app.conf.Feeds[name]
+
+	// atom:updated must always be a valid RFC 3339 date-time (RFC 4287
+	// §4.2.14); fall back to the file's mtime when there are no entries
+	// yet instead of emitting an empty element.
+	updated := modTime.UTC().Format(time.RFC3339)
+	startDate := modTime.UTC().Format("2006-01-02")
+	if len(entries) > 0 {
+		updated = entries[len(entries)-1].Timestamp.Format(time.RFC3339)
+		startDate = entries[0].Timestamp.Format("2006-01-02")
+	}
+
+	feed := atomFeed{
+		ID:      fmt.Sprintf("tag:%s,%s:%s", app.conf.Domain, startDate, name),
+		Title:   name,
+		Updated: updated,
+		Author:  atomPerson{Name: name},
+		Links: []atomLink{
+			{Rel: "self", Href: feedURL},
+			{Rel: "alternate", Href: htmlURL},
+		},
+	}
+
+	for _, e := range entries {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      fmt.Sprintf("tag:%s,%s:%s", app.conf.Domain, e.Timestamp.Format("2006-01-02"), entryID(e)),
+			Title:   entryTitle(e.Content),
+			Updated: e.Timestamp.Format(time.RFC3339),
+			Content: atomContent{Type: "html", Body: expandTwtxtContent(app, e.Content)},
+		})
+	}
+
+	return feed
+}
+
+// jsonFeed is a JSON Feed 1.1 document.
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url"`
+	Icon        string         `json:"icon,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url,omitempty"`
+	ContentText   string `json:"content_text"`
+	DatePublished string `json:"date_published"`
+}
+
+func buildJSONFeed(app *App, name string, entries []twtxtEntry) jsonFeed {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       name,
+		HomePageURL: app.conf.Feeds[name],
+		FeedURL:     fmt.Sprintf("%s/%s.json", app.conf.BaseURL, name),
+		Icon:        fmt.Sprintf("%s/avatar/%s", app.conf.BaseURL, name),
+	}
+
+	for _, e := range entries {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            entryID(e),
+			URL:           fmt.Sprintf("%s/%s", app.conf.BaseURL, name),
+			ContentText:   e.Content,
+			DatePublished: e.Timestamp.Format(time.RFC3339),
+		})
+	}
+
+	return feed
+}
+
+// expandTwtxtContent expands twtxt mentions (@name) and bare URLs into
+// HTML anchors for embedding in syndicated content. A mention only
+// becomes a link when name resolves to a known feed's source URL;
+// otherwise it's left as plain text rather than an unresolvable href.
+func expandTwtxtContent(app *App, content string) string {
+	var out strings.Builder
+
+	for _, word := range strings.Fields(content) {
+		switch {
+		case strings.HasPrefix(word, "@"):
+			name := strings.TrimPrefix(word, "@")
+			if url, ok := app.conf.Feeds[name]; ok {
+				fmt.Fprintf(&out, `<a href="%s">%s</a> `, html.EscapeString(url), html.EscapeString(word))
+			} else {
+				out.WriteString(html.EscapeString(word))
+				out.WriteString(" ")
+			}
+		case strings.HasPrefix(word, "http://"), strings.HasPrefix(word, "https://"):
+			escaped := html.EscapeString(word)
+			fmt.Fprintf(&out, `<a href="%s">%s</a> `, escaped, escaped)
+		default:
+			out.WriteString(html.EscapeString(word))
+			out.WriteString(" ")
+		}
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+// renderSyndicatedFeed writes the Atom or JSON Feed rendering of a
+// twtxt file to w.
+func (app *App) renderSyndicatedFeed(w http.ResponseWriter, r *http.Request, name, filename, format string) {
+	entries, err := readTwtxtEntries(filename)
+	if err != nil {
+		log.WithError(err).Error("error reading twtxt feed")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	modTime := time.Now()
+	if fileInfo, err := os.Stat(filename); err == nil {
+		modTime = fileInfo.ModTime()
+	}
+
+	switch format {
+	case "atom":
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		if r.Method == http.MethodHead {
+			return
+		}
+		fmt.Fprint(w, xml.Header)
+		if err := xml.NewEncoder(w).Encode(buildAtomFeed(app, name, entries, modTime)); err != nil {
+			log.WithError(err).Error("error encoding atom feed")
+		}
+	case "json":
+		w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+		if r.Method == http.MethodHead {
+			return
+		}
+		if err := json.NewEncoder(w).Encode(buildJSONFeed(app, name, entries)); err != nil {
+			log.WithError(err).Error("error encoding json feed")
+		}
+	}
+}
+
+// feedFormat determines which syndication format FeedHandler should
+// render for a given request, based on a `.atom`/`.json` suffix on the
+// route or, failing that, the Accept header.
+func feedFormat(r *http.Request, name string) (string, string) {
+	switch {
+	case strings.HasSuffix(name, ".atom"):
+		return strings.TrimSuffix(name, ".atom"), "atom"
+	case strings.HasSuffix(name, ".json"):
+		return strings.TrimSuffix(name, ".json"), "json"
+	}
+
+	switch accept.PreferredContentTypeLike(r.Header, "text/plain", "application/atom+xml", "application/feed+json") {
+	case "application/atom+xml":
+		return name, "atom"
+	case "application/feed+json":
+		return name, "json"
+	default:
+		return name, "txt"
+	}
+}
@@ -65,6 +65,11 @@ func (app *App) IndexHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method == http.MethodPost {
+		keyName, ok := app.authorizeSubmission(w, r)
+		if !ok {
+			return
+		}
+
 		url := r.FormValue("url")
 
 		if url == "" {
@@ -102,6 +107,10 @@ func (app *App) IndexHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if keyName != "" {
+			log.WithFields(log.Fields{"key": keyName, "feed": feed.Name}).Info("feed added via api key")
+		}
+
 		msg := fmt.Sprintf("Feed successfully added %s: %s", feed.Name, feed.URL)
 		if err := renderMessage(w, http.StatusCreated, "Success", msg); err != nil {
 			log.WithError(err).Error("error rendering message template")
@@ -114,8 +123,6 @@ func (app *App) IndexHandler(w http.ResponseWriter, r *http.Request) {
 
 func (app *App) FeedHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodHead || r.Method == http.MethodGet {
-		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-
 		vars := mux.Vars(r)
 
 		name := vars["name"]
@@ -124,6 +131,8 @@ func (app *App) FeedHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		name, format := feedFormat(r, name)
+
 		filename := filepath.Join(app.conf.Root, fmt.Sprintf("%s.txt", name))
 		if !FileExists(filename) {
 			log.Warnf("feed does not exist %s", name)
@@ -131,6 +140,13 @@ func (app *App) FeedHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if format == "atom" || format == "json" {
+			app.renderSyndicatedFeed(w, r, name, filename, format)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
 		fileInfo, err := os.Stat(filename)
 		if err != nil {
 			log.WithError(err).Error("os.Stat() error")
@@ -317,7 +333,11 @@ func (app *App) WeAreFeedsHandler(w http.ResponseWriter, r *http.Request) {
 
 func (app *App) FeedsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodHead || r.Method == http.MethodGet {
-		if accept.PreferredContentTypeLike(r.Header, "text/plain") == "text/plain" {
+		switch accept.PreferredContentTypeLike(r.Header, "text/plain", "text/x-opml", "application/xml") {
+		case "text/x-opml", "application/xml":
+			app.OPMLHandler(w, r)
+			return
+		case "text/plain":
 			app.WeAreFeedsHandler(w, r)
 			return
 		}
@@ -0,0 +1,80 @@
+// Package apikeys manages hashed API keys used to authenticate
+// automated feed submissions, loaded from a keys.toml file.
+package apikeys
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Key is a single named API key, stored hashed at rest.
+type Key struct {
+	Name string `toml:"name"`
+	Hash string `toml:"hash"`
+}
+
+// Store holds the set of known API keys.
+type Store struct {
+	Keys []Key `toml:"keys"`
+}
+
+// Load reads a keys.toml file, returning an empty Store if it does not
+// yet exist.
+func Load(path string) (*Store, error) {
+	store := &Store{}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return store, nil
+	}
+
+	if _, err := toml.DecodeFile(path, store); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Save writes the Store back to path as TOML.
+func (s *Store) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return toml.NewEncoder(f).Encode(s)
+}
+
+// Verify reports whether key matches any stored hash, returning the
+// label it was issued under.
+func (s *Store) Verify(key string) (name string, ok bool) {
+	for _, k := range s.Keys {
+		if bcrypt.CompareHashAndPassword([]byte(k.Hash), []byte(key)) == nil {
+			return k.Name, true
+		}
+	}
+	return "", false
+}
+
+// Add generates a fresh random API key for label, appends its hash to
+// the Store, and returns the plaintext key (shown once).
+func (s *Store) Add(label string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	key := base64.RawURLEncoding.EncodeToString(buf)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(key), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	s.Keys = append(s.Keys, Key{Name: label, Hash: string(hash)})
+
+	return key, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rss2twtxt_http_requests_total",
+		Help: "Total number of HTTP requests handled, by handler and status code.",
+	}, []string{"handler", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rss2twtxt_http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, by handler.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"handler"})
+
+	feedFetchTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rss2twtxt_feed_fetch_total",
+		Help: "Total number of upstream feed fetches, by feed and result.",
+	}, []string{"feed", "result"})
+
+	feedFetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rss2twtxt_feed_fetch_duration_seconds",
+		Help:    "Upstream feed fetch latency in seconds, by feed.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"feed"})
+
+	feedsTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "rss2twtxt_feeds_total",
+		Help: "Total number of feeds in the directory.",
+	})
+
+	feedEntriesTotal = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rss2twtxt_feed_entries_total",
+		Help: "Total number of twtxt entries written for a feed.",
+	}, []string{"feed"})
+)
+
+// ObserveFeedFetch records the outcome and latency of a single upstream
+// feed fetch for Prometheus scraping.
+func ObserveFeedFetch(feed, result string, d time.Duration) {
+	feedFetchTotal.WithLabelValues(feed, result).Inc()
+	feedFetchDuration.WithLabelValues(feed).Observe(d.Seconds())
+}
+
+// SetFeedsTotal updates the gauge tracking how many feeds are in the
+// directory.
+func SetFeedsTotal(n int) {
+	feedsTotal.Set(float64(n))
+}
+
+// SetFeedEntriesTotal updates the gauge tracking how many twtxt entries
+// a feed currently has on disk.
+func SetFeedEntriesTotal(feed string, n int) {
+	feedEntriesTotal.WithLabelValues(feed).Set(float64(n))
+}
+
+// MetricsHandler exposes the Prometheus registry at /metrics.
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// statusRecorder wraps a ResponseWriter to capture the status code and
+// byte count written, for logging and metrics middleware.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// remoteIP returns the client IP, honoring X-Forwarded-For only when
+// the direct peer is in the configured list of trusted proxies.
+func remoteIP(app *App, r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	for _, proxy := range app.conf.TrustedProxies {
+		if proxy == host {
+			if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+				return strings.TrimSpace(strings.Split(fwd, ",")[0])
+			}
+		}
+	}
+
+	return host
+}
+
+// InstrumentationMiddleware logs every request via logrus and records
+// it against the rss2twtxt_http_requests_total/duration metrics.
+func InstrumentationMiddleware(app *App, handler string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+			if rec.status == 0 {
+				// net/http sends an implicit 200 when nothing was written.
+				rec.status = http.StatusOK
+			}
+
+			duration := time.Since(start)
+			code := fmt.Sprintf("%d", rec.status)
+
+			httpRequestsTotal.WithLabelValues(handler, code).Inc()
+			httpRequestDuration.WithLabelValues(handler).Observe(duration.Seconds())
+
+			log.WithFields(log.Fields{
+				"handler":    handler,
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"status":     rec.status,
+				"bytes":      rec.bytes,
+				"duration":   duration.Seconds(),
+				"remote_ip":  remoteIP(app, r),
+				"user_agent": r.UserAgent(),
+			}).Info("handled request")
+		})
+	}
+}
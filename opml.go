@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// OPML is the root element of an OPML 2.0 document.
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    OPMLHead `xml:"head"`
+	Body    OPMLBody `xml:"body"`
+}
+
+// OPMLHead carries the document title.
+type OPMLHead struct {
+	Title string `xml:"title"`
+}
+
+// OPMLBody is the flat list of feed outlines.
+type OPMLBody struct {
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// OPMLOutline describes a single subscribed feed.
+type OPMLOutline struct {
+	Type    string `xml:"type,attr"`
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+}
+
+// NewOPML builds an OPML document from the aggregator's current feed list.
+func NewOPML(app *App) OPML {
+	opml := OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title: fmt.Sprintf("%s feeds", app.conf.Name),
+		},
+	}
+
+	for _, feed := range app.GetFeeds() {
+		opml.Body.Outlines = append(opml.Body.Outlines, OPMLOutline{
+			Type:    "rss",
+			Text:    feed.Name,
+			Title:   feed.Name,
+			XMLURL:  feed.URL,
+			HTMLURL: fmt.Sprintf("%s/%s", app.conf.BaseURL, feed.Name),
+		})
+	}
+
+	return opml
+}
+
+// OPMLHandler exports the full feed directory as an OPML 2.0 outline so
+// any RSS reader or aggregator can import it wholesale.
+func (app *App) OPMLHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodHead || r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		fmt.Fprint(w, xml.Header)
+		if err := xml.NewEncoder(w).Encode(NewOPML(app)); err != nil {
+			log.WithError(err).Error("error encoding opml")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+}
+
+// ImportResult records the outcome of importing a single OPML outline.
+type ImportResult struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportReport is the response body of ImportHandler: the per-entry
+// results processed so far, plus an overall error if the import didn't
+// fully complete (e.g. a failed conf.Save()).
+type ImportReport struct {
+	Results []ImportResult `json:"results"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// ImportHandler accepts an uploaded OPML file and adds every outline's
+// xmlUrl to the feed directory, reporting per-entry success/conflict/error.
+func (app *App) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	f, _, err := r.FormFile("opml")
+	if err != nil {
+		if err := renderMessage(w, http.StatusBadRequest, "Error", "No opml file supplied"); err != nil {
+			log.WithError(err).Error("error rendering message template")
+		}
+		return
+	}
+	defer f.Close()
+
+	results, err := app.importOPML(f)
+	if err != nil && results == nil {
+		// The file itself couldn't be parsed -- nothing was processed.
+		msg := fmt.Sprintf("Unable to parse opml file: %s", err)
+		if err := renderMessage(w, http.StatusBadRequest, "Error", msg); err != nil {
+			log.WithError(err).Error("error rendering message template")
+		}
+		return
+	}
+
+	report := ImportReport{Results: results}
+
+	status := http.StatusOK
+	if err != nil {
+		// Parsing succeeded and some feeds may have been added in memory,
+		// but persisting the config failed -- report what was processed
+		// alongside the real failure instead of a blanket parse error.
+		report.Error = fmt.Sprintf("opml parsed but failed to save config: %s", err)
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.WithError(err).Error("error encoding import results")
+	}
+}
+
+func (app *App) importOPML(f multipart.File) ([]ImportResult, error) {
+	var doc OPML
+	if err := xml.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	results := []ImportResult{}
+
+	for _, outline := range doc.Body.Outlines {
+		if outline.XMLURL == "" {
+			continue
+		}
+
+		feed, err := ValidateFeed(app.conf, outline.XMLURL)
+		if err != nil {
+			results = append(results, ImportResult{
+				URL:    outline.XMLURL,
+				Status: "error",
+				Error:  err.Error(),
+			})
+			continue
+		}
+
+		if _, ok := app.conf.Feeds[feed.Name]; ok {
+			results = append(results, ImportResult{
+				Name:   feed.Name,
+				URL:    feed.URL,
+				Status: "conflict",
+			})
+			continue
+		}
+
+		app.conf.Feeds[feed.Name] = feed.URL
+		results = append(results, ImportResult{
+			Name:   feed.Name,
+			URL:    feed.URL,
+			Status: "success",
+		})
+	}
+
+	if err := app.conf.Save(); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runPoller periodically refreshes every feed's .txt file until ctx is
+// cancelled. Run waits on app.pollWG (via pollOnce/WithPoll) before
+// shutting down, so a kill during a cycle can no longer truncate a
+// twtxt file or race a conf.Save().
+func (app *App) runPoller(ctx context.Context) {
+	ticker := time.NewTicker(app.conf.PollInterval)
+	defer ticker.Stop()
+
+	app.pollOnce()
+	app.MarkReady()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.pollOnce()
+		}
+	}
+}
+
+// pollOnce runs a single feed-update cycle across every configured feed,
+// recording per-feed fetch outcomes and latency for /metrics.
+func (app *App) pollOnce() {
+	app.WithPoll(func() {
+		for name, url := range app.conf.Feeds {
+			start := time.Now()
+			entries, err := UpdateFeed(app.conf, name, url)
+
+			result := "success"
+			if err != nil {
+				result = "error"
+				log.WithError(err).WithField("feed", name).Warn("error updating feed")
+			} else {
+				SetFeedEntriesTotal(name, entries)
+			}
+
+			ObserveFeedFetch(name, result, time.Since(start))
+		}
+
+		SetFeedsTotal(len(app.conf.Feeds))
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// NewRouter builds the application's full route table.
+func NewRouter(app *App) *mux.Router {
+	r := mux.NewRouter()
+
+	r.Handle("/", InstrumentationMiddleware(app, "index")(http.HandlerFunc(app.IndexHandler)))
+	r.Handle("/feeds", InstrumentationMiddleware(app, "feeds")(http.HandlerFunc(app.FeedsHandler)))
+	r.Handle("/media/{name}", InstrumentationMiddleware(app, "media")(http.HandlerFunc(app.MediaHandler)))
+	r.Handle("/avatar/{name}", InstrumentationMiddleware(app, "avatar")(http.HandlerFunc(app.AvatarHandler)))
+
+	// gorilla/mux matches routes in registration order and "/{name}" below
+	// matches any single path segment regardless of method, so it must be
+	// registered after every static route that should take precedence.
+	r.HandleFunc("/healthz", app.HealthzHandler)
+	r.HandleFunc("/readyz", app.ReadyzHandler)
+	r.HandleFunc("/feeds.opml", app.OPMLHandler)
+	r.HandleFunc("/import", app.ImportHandler).Methods(http.MethodPost)
+	r.HandleFunc("/csp-report", app.CSPReportHandler).Methods(http.MethodPost)
+	r.Handle("/metrics", MetricsHandler())
+
+	r.Handle("/{name}", InstrumentationMiddleware(app, "feed")(http.HandlerFunc(app.FeedHandler)))
+
+	r.Use(SecurityHeadersMiddleware(app))
+
+	return r
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Run starts the HTTP server and blocks until ctx is cancelled or the
+// process receives SIGINT/SIGTERM, then drains in-flight requests and
+// waits for the background feed poller before returning. This avoids
+// truncating a twtxt file mid-write or losing a conf.Save() on a kill
+// during a refresh cycle.
+func (app *App) Run(ctx context.Context) error {
+	pollCtx, cancelPoll := context.WithCancel(ctx)
+	defer cancelPoll()
+	app.pollWG.Add(1)
+	go func() {
+		defer app.pollWG.Done()
+		app.runPoller(pollCtx)
+	}()
+
+	srv := &http.Server{
+		Addr:    app.conf.Bind,
+		Handler: NewRouter(app),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	case <-sigCh:
+		log.Info("received shutdown signal")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), app.conf.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Error("error during graceful shutdown")
+	}
+
+	log.Info("waiting for in-flight feed updates to finish")
+	cancelPoll()
+	app.pollWG.Wait()
+
+	return nil
+}
+
+// WithPoll runs fn as one feed-poll cycle, registering it with Run's
+// WaitGroup so a shutdown waits for it to finish before exiting.
+func (app *App) WithPoll(fn func()) {
+	app.pollWG.Add(1)
+	defer app.pollWG.Done()
+	fn()
+}
+
+// MarkReady flips the readiness flag once config has loaded and the
+// initial feed-scan cycle has completed, for ReadyzHandler to observe.
+func (app *App) MarkReady() {
+	atomic.StoreInt32(&app.ready, 1)
+}
+
+// HealthzHandler reports 200 for as long as the process is alive.
+func (app *App) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "OK")
+}
+
+// ReadyzHandler reports 200 only once the initial feed-scan cycle has
+// completed and config has loaded; until then it reports 503 so a load
+// balancer or orchestrator won't route traffic to it.
+func (app *App) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&app.ready) == 0 {
+		http.Error(w, "Not Ready", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprint(w, "OK")
+}